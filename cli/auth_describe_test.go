@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestUpperSnake(t *testing.T) {
+	cases := map[string]string{
+		"profile":     "PROFILE",
+		"auth_server": "AUTH_SERVER",
+		"auth-server": "AUTH_SERVER",
+		"client-id":   "CLIENT_ID",
+		"":            "",
+	}
+
+	for in, want := range cases {
+		if got := upperSnake(in); got != want {
+			t.Errorf("upperSnake(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	if got, want := envVarName("client-id"), "APP_CLIENT_ID"; got != want {
+		t.Errorf("envVarName(%q) = %q, want %q", "client-id", got, want)
+	}
+}
+
+func TestFieldSource(t *testing.T) {
+	cmd := &cobra.Command{Use: "describe"}
+	var authServer string
+	cmd.Flags().StringVar(&authServer, "auth-server", "", "")
+
+	if got := fieldSource(cmd, "auth-server", ""); got != SourceDefault {
+		t.Errorf("unset flag with empty value: got %s, want %s", got, SourceDefault)
+	}
+
+	if err := cmd.Flags().Set("auth-server", "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fieldSource(cmd, "auth-server", "prod"); got != SourceFlag {
+		t.Errorf("explicitly set flag: got %s, want %s", got, SourceFlag)
+	}
+
+	t.Setenv("APP_CREDENTIAL", "ci-bot")
+	if got := fieldSource(cmd, "credential", "ci-bot"); got != SourceEnv {
+		t.Errorf("value from env var: got %s, want %s", got, SourceEnv)
+	}
+
+	if got := fieldSource(cmd, "issuer", "https://issuer.example"); got != SourceSettings {
+		t.Errorf("value from settings, no flag/env: got %s, want %s", got, SourceSettings)
+	}
+}
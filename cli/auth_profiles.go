@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// allProfileNames returns every configured profile name, with the active
+// profile first.
+func allProfileNames() []string {
+	names := []string{RunConfig.ProfileName}
+	for name := range RunConfig.Settings.Profiles {
+		if name != RunConfig.ProfileName {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// credentialsForProfile returns the credentials visible to the given
+// profile, read through whichever CredentialStore that profile is
+// configured to use.
+func credentialsForProfile(profileName string) (map[string]*Credential, error) {
+	store, _, err := resolveCredentialStoreForProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make(map[string]*Credential, len(names))
+	for _, name := range names {
+		credential, err := store.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		credentials[name] = credential
+	}
+	return credentials, nil
+}
+
+func initAuthProfilesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "Manage named profiles",
+	}
+
+	cmd.AddCommand(initAuthProfilesListCommand())
+	cmd.AddCommand(initAuthProfilesCreateCommand())
+	cmd.AddCommand(initAuthProfilesDeleteCommand())
+	cmd.AddCommand(initAuthProfilesUseCommand())
+	cmd.AddCommand(initAuthProfilesCopyCommand())
+
+	return cmd
+}
+
+func initAuthProfilesListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List configured profiles",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			for name := range RunConfig.Settings.Profiles {
+				marker := "  "
+				if name == RunConfig.ProfileName {
+					marker = "* "
+				}
+				fmt.Println(marker + name)
+			}
+		},
+	}
+}
+
+func initAuthProfilesCreateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a new empty profile",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.With().Str("profile", args[0]).Logger()
+
+			_, exists := RunConfig.Settings.Profiles[args[0]]
+			if exists {
+				logger.Fatal().Msgf("profile %q already exists", args[0])
+			}
+
+			updates := make(map[string]interface{})
+			updates[fmt.Sprintf("profiles.%s.auth_server_name", args[0])] = ""
+			if err := RunConfig.write(RunConfig.settingsPath, updates); err != nil {
+				logger.Fatal().Err(err)
+			}
+		},
+	}
+}
+
+func initAuthProfilesDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a profile",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.With().Str("profile", args[0]).Logger()
+
+			if args[0] == RunConfig.ProfileName {
+				logger.Fatal().Msg("cannot delete the active profile; switch profiles first with `auth profiles use`")
+			}
+
+			delete(RunConfig.Settings.Profiles, args[0])
+			if err := RunConfig.write(RunConfig.settingsPath, nil); err != nil {
+				logger.Fatal().Err(err)
+			}
+		},
+	}
+}
+
+func initAuthProfilesUseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Switch the active profile",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.With().Str("profile", args[0]).Logger()
+
+			_, exists := RunConfig.Settings.Profiles[args[0]]
+			if !exists {
+				logger.Fatal().Msgf("profile %q does not exist", args[0])
+			}
+
+			updates := make(map[string]interface{})
+			updates["default_profile"] = args[0]
+			if err := RunConfig.write(RunConfig.settingsPath, updates); err != nil {
+				logger.Fatal().Err(err)
+			}
+		},
+	}
+}
+
+func initAuthProfilesCopyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "copy <source> <destination>",
+		Short: "Copy a profile under a new name",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.With().Str("profile", args[1]).Logger()
+
+			source, exists := RunConfig.Settings.Profiles[args[0]]
+			if !exists {
+				logger.Fatal().Msgf("profile %q does not exist", args[0])
+			}
+
+			_, exists = RunConfig.Settings.Profiles[args[1]]
+			if exists {
+				logger.Fatal().Msgf("profile %q already exists", args[1])
+			}
+
+			// Copy every field a profile can carry, not just the two common
+			// to every handler: an mTLS profile (cert_file/key_file/ca_file/
+			// passphrase) or one pinned to a non-default credential_store
+			// would otherwise look copied but silently lose that
+			// configuration.
+			updates := make(map[string]interface{})
+			updates[fmt.Sprintf("profiles.%s.auth_server_name", args[1])] = source.AuthServerName
+			updates[fmt.Sprintf("profiles.%s.credential_name", args[1])] = source.CredentialName
+			updates[fmt.Sprintf("profiles.%s.server", args[1])] = source.Server
+			updates[fmt.Sprintf("profiles.%s.credential_store", args[1])] = source.CredentialStore
+			updates[fmt.Sprintf("profiles.%s.cert_file", args[1])] = source.CertFile
+			updates[fmt.Sprintf("profiles.%s.key_file", args[1])] = source.KeyFile
+			updates[fmt.Sprintf("profiles.%s.ca_file", args[1])] = source.CAFile
+			updates[fmt.Sprintf("profiles.%s.passphrase", args[1])] = source.Passphrase
+			if err := RunConfig.write(RunConfig.settingsPath, updates); err != nil {
+				logger.Fatal().Err(err)
+			}
+		},
+	}
+}
+
+// initAuthRunCommand re-invokes an arbitrary generated command once per
+// profile, so the same API call can be made across environments (e.g.
+// dev/stage/prod) without a shell loop.
+func initAuthRunCommand() *cobra.Command {
+	var profiles []string
+
+	cmd := &cobra.Command{
+		Use:                "run -- <command> [args...]",
+		Short:              "Run a command once per --profile",
+		Args:               cobra.MinimumNArgs(1),
+		DisableFlagParsing: false,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.With().Logger()
+
+			if len(profiles) == 0 {
+				logger.Fatal().Msg("at least one --profile is required")
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				logger.Fatal().Err(err)
+			}
+
+			for _, profileName := range profiles {
+				fmt.Fprintf(os.Stderr, "==> profile %s\n", profileName)
+
+				// <command> is an arbitrary top-level generated command, not
+				// a child of `auth`, so it doesn't understand `--profile`.
+				// Select its profile via the env var RunConfig resolves at
+				// load time instead, the same one users can set by hand.
+				run := exec.Command(executable, args...)
+				run.Env = append(os.Environ(), "APP_PROFILE="+profileName)
+				run.Stdout = os.Stdout
+				run.Stderr = os.Stderr
+				run.Stdin = os.Stdin
+
+				if err := run.Run(); err != nil {
+					logger.Error().Err(err).Str("profile", profileName).Msg("command failed")
+				}
+			}
+		},
+	}
+	cmd.Flags().StringArrayVar(&profiles, "profile", nil, "Profile to run the command for (may be repeated)")
+
+	return cmd
+}
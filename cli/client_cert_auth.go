@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+
+	"github.com/rs/zerolog"
+)
+
+// ClientCertAuthHandler authenticates requests with an X.509 client
+// certificate instead of a bearer token, for APIs that require mTLS.
+type ClientCertAuthHandler struct {
+	mu            sync.Mutex
+	configuredFor string
+}
+
+// ProfileKeys returns the key names for fields to store in the profile.
+func (c *ClientCertAuthHandler) ProfileKeys() []string {
+	return []string{"cert_file", "key_file", "ca_file", "passphrase"}
+}
+
+// loadClientCertificate reads the cert/key pair, decrypting the key first
+// when passphrase is non-empty, since tls.LoadX509KeyPair itself cannot
+// handle passphrase-protected keys.
+func loadClientCertificate(certFile, keyFile, passphrase string) (tls.Certificate, error) {
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read cert file: %w", err)
+	}
+
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	if passphrase != "" {
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return tls.Certificate{}, fmt.Errorf("no PEM data found in key file %q", keyFile)
+		}
+
+		if x509.IsEncryptedPEMBlock(block) {
+			decrypted, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+			if err != nil {
+				return tls.Certificate{}, fmt.Errorf("failed to decrypt key file %q with the configured passphrase: %w", keyFile, err)
+			}
+			keyPEM = pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: decrypted})
+		}
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// certIdentityPrefix tags an AccessToken value as a JSON-encoded
+// certIdentity rather than a real bearer token, so credentialSubject and
+// friends can tell the two apart.
+const certIdentityPrefix = "x509-identity:"
+
+// certIdentity is the certificate identity shown by `list-credentials` and
+// `auth describe` for a certificate-backed credential. It's packed into the
+// Credential's AccessToken field rather than oauth2.Token's Extra map:
+// Extra lives in oauth2.Token's unexported `raw` field, which
+// encoding/json silently drops on marshal, so it never survives a round
+// trip through any CredentialStore (file, keychain, env, or helper) - all
+// of which persist credentials as JSON. AccessToken is an ordinary string
+// field every store already serializes correctly.
+type certIdentity struct {
+	CommonName string `json:"common_name"`
+	Subject    string `json:"subject"`
+	Issuer     string `json:"issuer"`
+}
+
+func encodeCertIdentity(id certIdentity) (string, error) {
+	raw, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	return certIdentityPrefix + string(raw), nil
+}
+
+func decodeCertIdentity(accessToken string) (certIdentity, bool) {
+	if !strings.HasPrefix(accessToken, certIdentityPrefix) {
+		return certIdentity{}, false
+	}
+
+	var id certIdentity
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(accessToken, certIdentityPrefix)), &id); err != nil {
+		return certIdentity{}, false
+	}
+	return id, true
+}
+
+// credentialSubject returns the full certificate subject DN for a
+// certificate-backed credential, falling back to the JWT "sub" claim for
+// bearer-token credentials.
+func credentialSubject(credential *Credential) string {
+	if id, ok := decodeCertIdentity(credential.AccessToken); ok {
+		return id.Subject
+	}
+	return credential.TokenPayload.Subject()
+}
+
+// credentialIssuer returns the certificate issuer DN for a
+// certificate-backed credential, falling back to the JWT "iss" claim for
+// bearer-token credentials.
+func credentialIssuer(credential *Credential) string {
+	if id, ok := decodeCertIdentity(credential.AccessToken); ok {
+		return id.Issuer
+	}
+	return credential.TokenPayload.Issuer()
+}
+
+// credentialClientID returns the certificate's common name for a
+// certificate-backed credential, falling back to the JWT client ID claim
+// for bearer-token credentials.
+func credentialClientID(credential *Credential) string {
+	if id, ok := decodeCertIdentity(credential.AccessToken); ok {
+		return id.CommonName
+	}
+	return credential.TokenPayload.ClientID()
+}
+
+// ExecuteFlow validates that the configured key pair loads and records the
+// certificate's subject, issuer, and expiry so `list-credentials` shows
+// meaningful information even though no bearer token is involved.
+func (c *ClientCertAuthHandler) ExecuteFlow(log *zerolog.Logger) (*oauth2.Token, error) {
+	profile := RunConfig.GetProfile()
+
+	cert, err := loadClientCertificate(profile.CertFile, profile.KeyFile, profile.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+
+	accessToken, err := encodeCertIdentity(certIdentity{
+		CommonName: leaf.Subject.CommonName,
+		Subject:    leaf.Subject.String(),
+		Issuer:     leaf.Issuer.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode certificate identity: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: accessToken,
+		Expiry:      leaf.NotAfter,
+	}, nil
+}
+
+// OnRequest configures the shared Client's transport with the client
+// certificate, once per profile, composing onto whatever else (e.g. a UDS
+// dialer) has already been applied rather than replacing it.
+func (c *ClientCertAuthHandler) OnRequest(log *zerolog.Logger, request *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	profile := RunConfig.GetProfile()
+	if c.configuredFor == profile.CertFile {
+		return nil
+	}
+
+	cert, err := loadClientCertificate(profile.CertFile, profile.KeyFile, profile.Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if profile.CAFile != "" {
+		caCert, err := ioutil.ReadFile(profile.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("failed to parse CA file %q", profile.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	applyTransport(func(t *http.Transport) {
+		t.TLSClientConfig = tlsConfig
+	})
+	c.configuredFor = profile.CertFile
+
+	return nil
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// CredentialStore persists and retrieves named credentials. Built-in stores
+// cover the plain JSON secrets file, the OS keychain, environment
+// variables, and Docker-style credential helper binaries; downstream
+// generators can register additional stores (e.g. cloud secret managers)
+// via RegisterCredentialStore.
+type CredentialStore interface {
+	Get(name string) (*Credential, error)
+	Put(name string, credential *Credential) error
+	List() ([]string, error)
+	Delete(name string) error
+}
+
+// credentialStores is the registry of available store backends, keyed by
+// the name used with the --credential-store flag and the per-profile
+// `credential_store` setting.
+var credentialStores = map[string]CredentialStore{
+	"file": fileCredentialStore{},
+	"env":  envCredentialStore{},
+}
+
+func init() {
+	if keyringAvailable() {
+		credentialStores["keychain"] = keychainCredentialStore{}
+	}
+}
+
+// RegisterCredentialStore adds a new named credential store backend, for
+// example a cloud secret manager provided by a downstream generator.
+func RegisterCredentialStore(name string, store CredentialStore) {
+	credentialStores[name] = store
+}
+
+// RegisterCredentialHelper registers a Docker-style credential helper store
+// under the given name. The helper binary is invoked as
+// `<binary> get|store|erase|list`: get and erase read the credential name on
+// stdin, store reads a JSON credentialHelperEnvelope on stdin, get writes
+// that envelope on stdout, and list writes a JSON object keyed by credential
+// name, following Docker's credential helper protocol.
+func RegisterCredentialHelper(name, binary string) {
+	RegisterCredentialStore(name, credentialHelperStore{binary: binary})
+}
+
+// resolveCredentialStore returns the active store for the given name and
+// its resolved name. An empty name falls back to the active profile's
+// configured `credential_store` setting, and if that is also unset, to the
+// keychain when available and the plain file store otherwise, matching the
+// "never write refresh tokens to plaintext when a keychain is available"
+// rule.
+func resolveCredentialStore(name string) (CredentialStore, string, error) {
+	if name == "" {
+		name = RunConfig.GetProfile().CredentialStore
+	}
+	return resolveNamedCredentialStore(name)
+}
+
+// resolveCredentialStoreForProfile is like resolveCredentialStore, but
+// resolves against an arbitrary profile's configured store rather than the
+// currently active one. Used by commands like `list-credentials
+// --all-profiles` that read credentials belonging to other profiles.
+func resolveCredentialStoreForProfile(profileName string) (CredentialStore, string, error) {
+	profile := RunConfig.Settings.Profiles[profileName]
+	return resolveNamedCredentialStore(profile.CredentialStore)
+}
+
+// resolveNamedCredentialStore looks up the store for name, falling back to
+// the keychain when available and the plain file store otherwise.
+func resolveNamedCredentialStore(name string) (CredentialStore, string, error) {
+	if name == "" {
+		if _, ok := credentialStores["keychain"]; ok {
+			name = "keychain"
+		} else {
+			name = "file"
+		}
+	}
+
+	store, exists := credentialStores[name]
+	if !exists {
+		return nil, "", fmt.Errorf("unknown credential store %q", name)
+	}
+	return store, name, nil
+}
+
+// fileCredentialStore is the original plain JSON secrets file behavior,
+// kept as the universal fallback when no keychain is available.
+type fileCredentialStore struct{}
+
+func (fileCredentialStore) Get(name string) (*Credential, error) {
+	credential, exists := RunConfig.Secrets.Credentials[name]
+	if !exists {
+		return nil, fmt.Errorf("no credential %q in secrets file", name)
+	}
+	return credential, nil
+}
+
+func (fileCredentialStore) Put(name string, credential *Credential) error {
+	return RunConfig.UpdateCredentialsToken(name, credential.Token)
+}
+
+func (fileCredentialStore) List() ([]string, error) {
+	names := make([]string, 0, len(RunConfig.Secrets.Credentials))
+	for name := range RunConfig.Secrets.Credentials {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (fileCredentialStore) Delete(name string) error {
+	delete(RunConfig.Secrets.Credentials, name)
+	return RunConfig.write(RunConfig.secretsPath, nil)
+}
+
+// keychainCredentialStore stores credentials in the OS-native secret store
+// via the Darwin Keychain, GNOME libsecret, or Windows Credential Manager.
+type keychainCredentialStore struct{}
+
+const keyringService = "openapi-cli-generator"
+
+func keyringAvailable() bool {
+	_, err := keyring.Get(keyringService, "__probe__")
+	return err == nil || err == keyring.ErrNotFound
+}
+
+func (keychainCredentialStore) Get(name string) (*Credential, error) {
+	raw, err := keyring.Get(keyringService, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var credential Credential
+	if err := json.Unmarshal([]byte(raw), &credential); err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (keychainCredentialStore) Put(name string, credential *Credential) error {
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(keyringService, name, string(raw)); err != nil {
+		return err
+	}
+	return addToCredentialIndex("keychain", name)
+}
+
+func (keychainCredentialStore) List() ([]string, error) {
+	return RunConfig.Settings.CredentialIndex["keychain"], nil
+}
+
+func (keychainCredentialStore) Delete(name string) error {
+	if err := keyring.Delete(keyringService, name); err != nil {
+		return err
+	}
+	return removeFromCredentialIndex("keychain", name)
+}
+
+// credentialIndex records, in the settings file, the names of credentials
+// stored in backends that can't enumerate their own contents. Only the OS
+// keychain store uses it; credential helper stores are listed through the
+// helper's own `list` action instead. Without it, the first `list-credentials` after `add-credentials`
+// on a machine with a working keychain would crash instead of rendering an
+// empty or partial table, since the keychain itself has no way to ask "what
+// did we ever store here?".
+func addToCredentialIndex(storeName, name string) error {
+	for _, existing := range RunConfig.Settings.CredentialIndex[storeName] {
+		if existing == name {
+			return nil
+		}
+	}
+
+	names := append(append([]string{}, RunConfig.Settings.CredentialIndex[storeName]...), name)
+	updates := make(map[string]interface{})
+	updates[fmt.Sprintf("credential_index.%s", storeName)] = names
+	return RunConfig.write(RunConfig.settingsPath, updates)
+}
+
+func removeFromCredentialIndex(storeName, name string) error {
+	existing := RunConfig.Settings.CredentialIndex[storeName]
+	names := make([]string, 0, len(existing))
+	for _, candidate := range existing {
+		if candidate != name {
+			names = append(names, candidate)
+		}
+	}
+
+	updates := make(map[string]interface{})
+	updates[fmt.Sprintf("credential_index.%s", storeName)] = names
+	return RunConfig.write(RunConfig.settingsPath, updates)
+}
+
+// envCredentialStore is a read-only store that looks up credentials from
+// environment variables of the form APP_TOKEN_<NAME>, useful for CI where
+// secrets are injected by the pipeline rather than a file on disk.
+type envCredentialStore struct{}
+
+func envVarForCredential(name string) string {
+	return "APP_TOKEN_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+func (envCredentialStore) Get(name string) (*Credential, error) {
+	raw, ok := os.LookupEnv(envVarForCredential(name))
+	if !ok {
+		return nil, fmt.Errorf("no %s environment variable set", envVarForCredential(name))
+	}
+
+	var credential Credential
+	if err := json.Unmarshal([]byte(raw), &credential); err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (envCredentialStore) Put(name string, credential *Credential) error {
+	return fmt.Errorf("the env credential store is read-only; set %s instead", envVarForCredential(name))
+}
+
+func (envCredentialStore) List() ([]string, error) {
+	return nil, fmt.Errorf("listing credentials is not supported by the env credential store")
+}
+
+func (envCredentialStore) Delete(name string) error {
+	return fmt.Errorf("the env credential store is read-only; unset %s instead", envVarForCredential(name))
+}
+
+// credentialHelperStore shells out to a user-configured binary that reads
+// and writes JSON on stdio, modeled after Docker's credential helper
+// protocol (`<binary> get|store|erase|list`).
+type credentialHelperStore struct {
+	binary string
+}
+
+func (s credentialHelperStore) run(action, input string) (string, error) {
+	cmd := exec.Command(s.binary, action)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("credential helper %q %s failed: %w: %s", s.binary, action, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// credentialHelperEnvelope is the JSON shape Docker-style credential helpers
+// read and write on stdio: `store` takes {ServerURL, Secret} on stdin, and
+// `get` returns the same shape on stdout. Get and Put must agree on this
+// envelope, or a helper that persists exactly what it's given will hand
+// back an unwrapped {ServerURL, Secret} object that silently fails to
+// unmarshal into the fields of *Credential.
+type credentialHelperEnvelope struct {
+	ServerURL string      `json:"ServerURL"`
+	Secret    *Credential `json:"Secret"`
+}
+
+func (s credentialHelperStore) Get(name string) (*Credential, error) {
+	out, err := s.run("get", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope credentialHelperEnvelope
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Secret == nil {
+		return nil, fmt.Errorf("credential helper %q returned no secret for %q", s.binary, name)
+	}
+	return envelope.Secret, nil
+}
+
+func (s credentialHelperStore) Put(name string, credential *Credential) error {
+	raw, err := json.Marshal(credentialHelperEnvelope{ServerURL: name, Secret: credential})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.run("store", string(raw))
+	return err
+}
+
+func (s credentialHelperStore) List() ([]string, error) {
+	out, err := s.run("list", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var names map[string]string
+	if err := json.Unmarshal([]byte(out), &names); err != nil {
+		return nil, err
+	}
+
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	return list, nil
+}
+
+func (s credentialHelperStore) Delete(name string) error {
+	_, err := s.run("erase", name)
+	return err
+}
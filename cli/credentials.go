@@ -8,10 +8,11 @@ import (
 	"os"
 	"strings"
 
-	"github.com/olekukonko/tablewriter"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+
+	"github.com/Mystery-College-of-The-Adapts/openapi-cli-generator/cli/output"
 )
 
 // AuthHandler describes a handler that can be called on a request to inject
@@ -32,20 +33,55 @@ var AuthHandlers = make(map[string]AuthHandler)
 var authInitialized bool
 var authCommand *cobra.Command
 
+// outputFormat and noHeaders back the --output/--no-headers flags shared by
+// every auth subcommand that renders tabular data.
+var outputFormat string
+var noHeaders bool
+
+// newOutputRenderer builds a Renderer for the current --output flag,
+// defaulting to a table when stdout is a TTY and to JSON otherwise so
+// scripts get machine-readable output without having to pass a flag.
+func newOutputRenderer() (output.Renderer, error) {
+	format := outputFormat
+	if format == "" {
+		if fi, err := os.Stdout.Stat(); err == nil && (fi.Mode()&os.ModeCharDevice) != 0 {
+			format = "table"
+		} else {
+			format = "json"
+		}
+	}
+	return output.New(format, noHeaders)
+}
+
 // AddAuthCommands sets up basic commands and the credentials file so that new auth
 // handlers can be registered. This MUST be called only after auth handlers have
 // been set up through UseAuth.
 func AddAuthCommands(parent *cobra.Command) {
+	var profileFlag string
+
 	// Add base auth commands
 	authCommand = &cobra.Command{
 		Use:   "auth",
 		Short: "Authentication settings",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if profileFlag != "" {
+				RunConfig.ProfileName = profileFlag
+			} else if envProfile := os.Getenv("APP_PROFILE"); envProfile != "" {
+				RunConfig.ProfileName = envProfile
+			}
+		},
 	}
+	authCommand.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile to operate on (defaults to the active profile)")
+	authCommand.PersistentFlags().StringVar(&outputFormat, "output", "", "Output format: table, json, yaml, or jsonpath=<expr> (default table on a TTY, json otherwise)")
+	authCommand.PersistentFlags().BoolVar(&noHeaders, "no-headers", false, "Don't print table headers")
 
 	authCommand.AddCommand(initAuthAddServersCommand())
 	authCommand.AddCommand(initAuthAddCredentialsCommand())
 	authCommand.AddCommand(initAuthListServersCommand())
 	authCommand.AddCommand(initAuthListCredentialsCommand())
+	authCommand.AddCommand(initAuthDescribeCommand())
+	authCommand.AddCommand(initAuthProfilesCommand())
+	authCommand.AddCommand(initAuthRunCommand())
 
 	parent.AddCommand(authCommand)
 }
@@ -60,6 +96,10 @@ func initAuthAddServersCommand() *cobra.Command {
 		Run:  func(cmd *cobra.Command, args []string) {
 			logger := log.With().Str("profile", RunConfig.ProfileName).Logger()
 
+			if err := ValidateServerAddress(args[0]); err != nil {
+				logger.Fatal().Err(err)
+			}
+
 			authServerName := strings.Replace(args[0], ".", "-", -1)
 			_, exists := RunConfig.Settings.AuthServers[authServerName]
 			if exists {
@@ -90,6 +130,7 @@ func initAuthAddServersCommand() *cobra.Command {
 
 func initAuthAddCredentialsCommand() *cobra.Command {
 	var authServerName string
+	var credentialStoreName string
 
 	cmd := &cobra.Command{
 		Use:   "add-credentials",
@@ -99,8 +140,13 @@ func initAuthAddCredentialsCommand() *cobra.Command {
 			logger := log.With().Str("profile", RunConfig.ProfileName).Logger()
 
 			credentialName := strings.Replace(args[0], ".", "-", -1)
-			_, exists := RunConfig.Secrets.Credentials[credentialName]
-			if exists {
+
+			store, storeName, err := resolveCredentialStore(credentialStoreName)
+			if err != nil {
+				logger.Fatal().Err(err)
+			}
+
+			if _, err := store.Get(credentialName); err == nil {
 				logger.Fatal().Msgf("credential %q already exists", credentialName)
 			}
 
@@ -109,13 +155,21 @@ func initAuthAddCredentialsCommand() *cobra.Command {
 			if err != nil {
 				logger.Fatal().Err(err)
 			}
-			err = RunConfig.UpdateCredentialsToken(credentialName, token)
-			if err != nil {
+			if err := store.Put(credentialName, &Credential{Token: token}); err != nil {
+				logger.Fatal().Err(err)
+			}
+
+			// Record which backend this credential landed in so later reads
+			// (list-credentials, auth describe, OnRequest) know where to look.
+			updates := make(map[string]interface{})
+			updates[fmt.Sprintf("profiles.%s.credential_store", RunConfig.ProfileName)] = storeName
+			if err := RunConfig.write(RunConfig.settingsPath, updates); err != nil {
 				logger.Fatal().Err(err)
 			}
 		},
 	}
 	cmd.Flags().StringVar(&authServerName, "auth-server-name", "", "")
+	cmd.Flags().StringVar(&credentialStoreName, "credential-store", "", "Backend to store the credential in: file, keychain, or a registered helper (default: keychain if available, else file)")
 
 	/*
 	SetCustomFlags(cmd)
@@ -128,25 +182,76 @@ func initAuthAddCredentialsCommand() *cobra.Command {
 }
 
 func initAuthListCredentialsCommand() *cobra.Command {
+	var allProfiles bool
+
 	cmd := &cobra.Command{
 		Use:     "list-credentials",
 		Short:   "List available credentials",
 		Args:    cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			credentials := RunConfig.Secrets.Credentials
-			if credentials != nil {
-				table := tablewriter.NewWriter(os.Stdout)
-				table.SetHeader([]string{"Name", "Client ID", "Issuer"})
+			renderer, err := newOutputRenderer()
+			if err != nil {
+				log.Fatal().Err(err)
+			}
+
+			var rows []output.Row
 
-				for credentialName, credential := range credentials {
-					table.Append([]string{credentialName, credential.TokenPayload.ClientID(), credential.TokenPayload.Issuer()})
+			if allProfiles {
+				for _, profileName := range allProfileNames() {
+					credentials, err := credentialsForProfile(profileName)
+					if err != nil {
+						log.Warn().Err(err).Str("profile", profileName).Msg("skipping profile: could not list its credentials")
+						continue
+					}
+					for credentialName, credential := range credentials {
+						name := credentialName
+						if profileName == RunConfig.ProfileName {
+							name = "* " + name
+						}
+						rows = append(rows, output.Row{
+							{Name: "Profile", Value: profileName},
+							{Name: "Name", Value: name},
+							{Name: "Client ID", Value: credentialClientID(credential)},
+							{Name: "Issuer", Value: credentialIssuer(credential)},
+						})
+					}
 				}
-				table.Render()
 			} else {
+				store, _, err := resolveCredentialStore("")
+				if err != nil {
+					log.Fatal().Err(err)
+				}
+
+				names, err := store.List()
+				if err != nil {
+					log.Warn().Err(err).Msg("credential store can't list names; the table below may be empty")
+					names = nil
+				}
+
+				for _, credentialName := range names {
+					credential, err := store.Get(credentialName)
+					if err != nil {
+						log.Fatal().Err(err)
+					}
+					rows = append(rows, output.Row{
+						{Name: "Name", Value: credentialName},
+						{Name: "Client ID", Value: credentialClientID(credential)},
+						{Name: "Issuer", Value: credentialIssuer(credential)},
+					})
+				}
+			}
+
+			if len(rows) == 0 {
 				fmt.Printf("No credentials configured. Use `%s auth addCredentials` to add one.\n", Root.CommandPath())
+				return
+			}
+
+			if err := renderer.Render(os.Stdout, rows); err != nil {
+				log.Fatal().Err(err)
 			}
 		},
 	}
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "Render a combined table of credentials across every profile")
 	return cmd
 }
 
@@ -156,18 +261,27 @@ func initAuthListServersCommand() *cobra.Command {
 		Short:   "List available authentication servers",
 		Args:    cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			authServers := RunConfig.Settings.AuthServers
-			if authServers != nil {
-				table := tablewriter.NewWriter(os.Stdout)
-				table.SetHeader([]string{"Name", "Client ID", "Issuer"})
-
-				// For each type name, draw a table with the relevant profileName keys
-				for authServerName, authServer := range authServers {
-					table.Append([]string{authServerName, authServer.ClientID, authServer.Issuer})
-				}
-				table.Render()
-			} else {
+			renderer, err := newOutputRenderer()
+			if err != nil {
+				log.Fatal().Err(err)
+			}
+
+			var rows []output.Row
+			for authServerName, authServer := range RunConfig.Settings.AuthServers {
+				rows = append(rows, output.Row{
+					{Name: "Name", Value: authServerName},
+					{Name: "Client ID", Value: authServer.ClientID},
+					{Name: "Issuer", Value: authServer.Issuer},
+				})
+			}
+
+			if len(rows) == 0 {
 				fmt.Printf("No authentication servers configured. Use `%s auth addServer` to add one.\n", Root.CommandPath())
+				return
+			}
+
+			if err := renderer.Render(os.Stdout, rows); err != nil {
+				log.Fatal().Err(err)
 			}
 		},
 	}
@@ -179,6 +293,10 @@ func initAuthListServersCommand() *cobra.Command {
 // to always pass a value for the type name.
 func UseAuth(typeName string, handler AuthHandler) {
 	if !authInitialized {
+		// If the active profile targets a Unix domain socket rather than a
+		// TCP host:port, install a dialer for it before anything else runs.
+		configureUnixSocketTransport(RunConfig.GetProfile().Server)
+
 		// Install auth middleware
 		Client.UseRequest(func(ctx *context.Context, h context.Handler) {
 			handler := AuthHandlers[RunConfig.GetProfile().AuthServerName]
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/rs/zerolog"
+)
+
+// deviceCodeResponse is the response body from an issuer's device
+// authorization endpoint, per RFC 8628 section 3.2.
+type deviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// deviceTokenResponse is the response body from the token endpoint while
+// polling for the result of a device authorization grant.
+type deviceTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+}
+
+// DeviceCodeAuthHandler implements the OAuth 2.0 Device Authorization Grant
+// (RFC 8628), allowing users on headless machines or CI without a browser
+// to authenticate without ever handling a client secret.
+type DeviceCodeAuthHandler struct {
+	ClientID string
+	Issuer   string
+	Scope    string
+}
+
+// ProfileKeys returns the key names for fields to store in the profile.
+func (d *DeviceCodeAuthHandler) ProfileKeys() []string {
+	return []string{"client_id", "issuer", "scope"}
+}
+
+// ExecuteFlow starts the device authorization grant, prompts the user to
+// visit the verification URL, and polls the token endpoint until the user
+// completes (or abandons) the flow.
+func (d *DeviceCodeAuthHandler) ExecuteFlow(log *zerolog.Logger) (*oauth2.Token, error) {
+	resp, err := http.PostForm(d.Issuer+"/oauth/device/code", url.Values{
+		"client_id": {d.ClientID},
+		"scope":     {d.Scope},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var dcr deviceCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dcr); err != nil {
+		return nil, err
+	}
+
+	prompt := dcr.VerificationURIComplete
+	if prompt == "" {
+		prompt = fmt.Sprintf("%s (code: %s)", dcr.VerificationURI, dcr.UserCode)
+	}
+	fmt.Fprintf(os.Stderr, "To continue, open the following URL in a browser:\n\n  %s\n\n", prompt)
+
+	interval := time.Duration(dcr.Interval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	expiry := time.Now().Add(time.Duration(dcr.ExpiresIn) * time.Second)
+
+	for {
+		if time.Now().After(expiry) {
+			return nil, fmt.Errorf("device code expired before authorization completed")
+		}
+
+		time.Sleep(interval)
+
+		tr, err := http.PostForm(d.Issuer+"/oauth/token", url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {dcr.DeviceCode},
+			"client_id":   {d.ClientID},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var dtr deviceTokenResponse
+		err = json.NewDecoder(tr.Body).Decode(&dtr)
+		tr.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		switch dtr.Error {
+		case "":
+			return &oauth2.Token{
+				AccessToken:  dtr.AccessToken,
+				RefreshToken: dtr.RefreshToken,
+				TokenType:    dtr.TokenType,
+				Expiry:       time.Now().Add(time.Duration(dtr.ExpiresIn) * time.Second),
+			}, nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		case "expired_token":
+			return nil, fmt.Errorf("device code expired before authorization completed")
+		case "access_denied":
+			return nil, fmt.Errorf("authorization was denied")
+		default:
+			return nil, fmt.Errorf("device authorization failed: %s", dtr.Error)
+		}
+	}
+}
+
+// OnRequest refreshes the stored access token if it has expired, then sets
+// the Authorization header on the outgoing request.
+func (d *DeviceCodeAuthHandler) OnRequest(log *zerolog.Logger, request *http.Request) error {
+	profile := RunConfig.GetProfile()
+
+	store, _, err := resolveCredentialStore("")
+	if err != nil {
+		return err
+	}
+
+	credential, err := store.Get(profile.CredentialName)
+	if err != nil {
+		return fmt.Errorf("no credential %q configured: %w", profile.CredentialName, err)
+	}
+
+	accessToken := credential.AccessToken
+	if credential.Expiry.Before(time.Now()) {
+		source := oauth2.Config{
+			ClientID: d.ClientID,
+			Endpoint: oauth2.Endpoint{
+				TokenURL: d.Issuer + "/oauth/token",
+			},
+		}.TokenSource(request.Context(), credential.Token)
+
+		refreshed, err := source.Token()
+		if err != nil {
+			return fmt.Errorf("failed to refresh access token: %w", err)
+		}
+
+		// Write the refreshed token back through the same store the
+		// credential was read from, so a keychain-backed credential never
+		// gets its refresh token dropped into the plain JSON file.
+		if err := store.Put(profile.CredentialName, &Credential{Token: refreshed}); err != nil {
+			return err
+		}
+		accessToken = refreshed.AccessToken
+	}
+
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	return nil
+}
+
+// DryRunRequest sets the Authorization header using whatever access token is
+// already stored, without refreshing an expired one, making a network call,
+// or writing anything back to disk. It implements DryRunAuthHandler for
+// `auth describe`.
+func (d *DeviceCodeAuthHandler) DryRunRequest(log *zerolog.Logger, request *http.Request) error {
+	profile := RunConfig.GetProfile()
+
+	store, _, err := resolveCredentialStore("")
+	if err != nil {
+		return err
+	}
+
+	credential, err := store.Get(profile.CredentialName)
+	if err != nil {
+		return fmt.Errorf("no credential %q configured: %w", profile.CredentialName, err)
+	}
+
+	request.Header.Set("Authorization", "Bearer "+credential.AccessToken)
+	return nil
+}
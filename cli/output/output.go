@@ -0,0 +1,117 @@
+// Package output provides pipe-friendly rendering of tabular CLI output as
+// a table, JSON, YAML, or a JSONPath-filtered projection, so that auth
+// subcommands are as scriptable in CI as they are readable in a terminal.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+// Field is a single named value in a Row, ordered as it should be displayed.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Row is an ordered set of fields, e.g. one credential or server entry.
+type Row []Field
+
+// Renderer writes a set of rows to an io.Writer in some output format.
+type Renderer interface {
+	Render(w io.Writer, rows []Row) error
+}
+
+// New builds a Renderer for the given --output value: "table", "json",
+// "yaml", or "jsonpath=<expr>". noHeaders suppresses the table header row
+// and is ignored by the other formats.
+func New(format string, noHeaders bool) (Renderer, error) {
+	switch {
+	case format == "" || format == "table":
+		return tableRenderer{noHeaders: noHeaders}, nil
+	case format == "json":
+		return jsonRenderer{}, nil
+	case format == "yaml":
+		return yamlRenderer{}, nil
+	case strings.HasPrefix(format, "jsonpath="):
+		return jsonPathRenderer{expr: strings.TrimPrefix(format, "jsonpath=")}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q: want table, json, yaml, or jsonpath=<expr>", format)
+	}
+}
+
+type tableRenderer struct {
+	noHeaders bool
+}
+
+func (r tableRenderer) Render(w io.Writer, rows []Row) error {
+	table := tablewriter.NewWriter(w)
+
+	if len(rows) > 0 && !r.noHeaders {
+		headers := make([]string, len(rows[0]))
+		for i, field := range rows[0] {
+			headers[i] = field.Name
+		}
+		table.SetHeader(headers)
+	}
+
+	for _, row := range rows {
+		values := make([]string, len(row))
+		for i, field := range row {
+			values[i] = field.Value
+		}
+		table.Append(values)
+	}
+
+	table.Render()
+	return nil
+}
+
+func rowsToMaps(rows []Row) []map[string]string {
+	out := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		m := make(map[string]string, len(row))
+		for _, field := range row {
+			m[field.Name] = field.Value
+		}
+		out[i] = m
+	}
+	return out
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, rows []Row) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rowsToMaps(rows))
+}
+
+type yamlRenderer struct{}
+
+func (yamlRenderer) Render(w io.Writer, rows []Row) error {
+	return yaml.NewEncoder(w).Encode(rowsToMaps(rows))
+}
+
+type jsonPathRenderer struct {
+	expr string
+}
+
+func (r jsonPathRenderer) Render(w io.Writer, rows []Row) error {
+	var data interface{} = rowsToMaps(rows)
+
+	result, err := jsonpath.Get(r.expr, data)
+	if err != nil {
+		return fmt.Errorf("invalid jsonpath expression %q: %w", r.expr, err)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
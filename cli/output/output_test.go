@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func sampleRows() []Row {
+	return []Row{
+		{{Name: "Name", Value: "prod"}, {Name: "Issuer", Value: "https://issuer.example"}},
+		{{Name: "Name", Value: "dev"}, {Name: "Issuer", Value: "https://dev.issuer.example"}},
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("xml", false); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestTableRenderer(t *testing.T) {
+	renderer, err := New("table", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, sampleRows()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "NAME") || !strings.Contains(out, "ISSUER") {
+		t.Errorf("expected headers in table output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "prod") || !strings.Contains(out, "dev") {
+		t.Errorf("expected row values in table output, got:\n%s", out)
+	}
+}
+
+func TestTableRendererNoHeaders(t *testing.T) {
+	renderer, err := New("table", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, sampleRows()); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "NAME") {
+		t.Errorf("expected no header row, got:\n%s", buf.String())
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	renderer, err := New("json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, sampleRows()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `"Name": "prod"`) || !strings.Contains(out, `"Issuer": "https://dev.issuer.example"`) {
+		t.Errorf("unexpected JSON output:\n%s", out)
+	}
+}
+
+func TestYAMLRenderer(t *testing.T) {
+	renderer, err := New("yaml", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, sampleRows()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Name: prod") || !strings.Contains(out, "Issuer: https://dev.issuer.example") {
+		t.Errorf("unexpected YAML output:\n%s", out)
+	}
+}
+
+func TestJSONPathRenderer(t *testing.T) {
+	renderer, err := New("jsonpath=$[*].Name", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, sampleRows()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "prod") || !strings.Contains(out, "dev") {
+		t.Errorf("unexpected jsonpath output:\n%s", out)
+	}
+}
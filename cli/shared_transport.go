@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"net/http"
+	"sync"
+
+	"gopkg.in/h2non/gentleman.v2/plugins/transport"
+)
+
+// sharedTransport is the single *http.Transport backing the shared Client.
+// Every feature that needs to customize the transport (Unix domain socket
+// dialing, mTLS) mutates this one instance through applyTransport instead
+// of handing Client a brand new *http.Transport of its own; otherwise each
+// feature's Client.Use(transport.Set(...)) call would silently clobber
+// whatever the last one configured, since gentleman's transport plugin just
+// replaces the client's Transport wholesale.
+var (
+	sharedTransportMu sync.Mutex
+	sharedTransport   *http.Transport
+)
+
+// applyTransport mutates the fields mutate sets on the shared transport and
+// (re-)installs it on Client. Safe to call from multiple auth/transport
+// features targeting the same profile; each call only touches the fields
+// it cares about, leaving whatever another feature already configured
+// (e.g. a UDS DialContext alongside an mTLS TLSClientConfig) intact.
+func applyTransport(mutate func(t *http.Transport)) {
+	sharedTransportMu.Lock()
+	defer sharedTransportMu.Unlock()
+
+	if sharedTransport == nil {
+		sharedTransport = &http.Transport{}
+	}
+	mutate(sharedTransport)
+	Client.Use(transport.Set(sharedTransport))
+}
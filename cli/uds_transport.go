@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// unixSocketPrefix is the scheme used to target a Unix domain socket
+// instead of a TCP host:port, e.g. "unix:///var/run/myapi.sock/v1".
+const unixSocketPrefix = "unix://"
+
+// ParseUnixSocketServer splits a `unix://` server value into the socket
+// path on disk and the optional URL path prefix that follows it, so that
+// profiles can target a co-located daemon's loopback socket the same way
+// tools that expose both TCP and UDS listeners do.
+//
+// The socket path is taken to be the longest slash-delimited prefix of the
+// remainder that exists on disk and is not a directory; anything after it
+// is treated as a URL path prefix. If no such prefix exists yet (e.g. while validating
+// `add-server` before the daemon has started), the whole remainder is
+// taken to be the socket path and the URL path prefix is empty.
+func ParseUnixSocketServer(server string) (socketPath string, urlPrefix string, ok bool) {
+	if !strings.HasPrefix(server, unixSocketPrefix) {
+		return "", "", false
+	}
+
+	remainder := strings.TrimPrefix(server, unixSocketPrefix)
+	if remainder == "" {
+		return "", "", false
+	}
+
+	segments := strings.Split(remainder, "/")
+	for i := len(segments); i > 0; i-- {
+		candidate := strings.Join(segments[:i], "/")
+		if fi, err := os.Stat(candidate); err == nil && !fi.IsDir() {
+			return candidate, strings.Join(segments[i:], "/"), true
+		}
+	}
+
+	return remainder, "", true
+}
+
+// ValidateServerAddress checks that a server value given to `add-server` is
+// a host:port, an http:// or https:// URL, or a `unix://` socket path, so typos are caught
+// before being written to the settings file.
+func ValidateServerAddress(server string) error {
+	if strings.HasPrefix(server, unixSocketPrefix) {
+		socketPath, _, _ := ParseUnixSocketServer(server)
+		if socketPath == "" {
+			return fmt.Errorf("unix socket server %q is missing a socket path", server)
+		}
+		return nil
+	}
+
+	if !strings.Contains(server, ":") && !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
+		return fmt.Errorf("server %q must be a host:port, a URL, or a unix:// socket path", server)
+	}
+	return nil
+}
+
+// configureUnixSocketTransport installs a custom net.Dial-based DialContext
+// on the shared Client's transport when the given server targets a Unix
+// domain socket. It is a no-op, returning false, for ordinary TCP servers.
+// It composes onto the shared transport via applyTransport rather than
+// replacing it outright, so a profile that also configures mTLS (via
+// ClientCertAuthHandler.OnRequest) doesn't clobber this dialer or vice
+// versa.
+func configureUnixSocketTransport(server string) bool {
+	socketPath, _, ok := ParseUnixSocketServer(server)
+	if !ok {
+		return false
+	}
+
+	applyTransport(func(t *http.Transport) {
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		}
+	})
+
+	return true
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseUnixSocketServer(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "app.sock")
+	if err := os.WriteFile(socketPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	gotSocket, gotPrefix, ok := ParseUnixSocketServer("unix://" + socketPath + "/v1/widgets")
+	if !ok {
+		t.Fatalf("ParseUnixSocketServer returned ok=false for a valid unix:// server")
+	}
+	if gotSocket != socketPath {
+		t.Errorf("socket path = %q, want %q", gotSocket, socketPath)
+	}
+	if gotPrefix != "v1/widgets" {
+		t.Errorf("url prefix = %q, want %q", gotPrefix, "v1/widgets")
+	}
+
+	if _, _, ok := ParseUnixSocketServer("https://example.com"); ok {
+		t.Error("expected ok=false for a non-unix:// server")
+	}
+
+	if _, _, ok := ParseUnixSocketServer("unix://"); ok {
+		t.Error("expected ok=false for a unix:// server with no path at all")
+	}
+
+	notOnDisk := filepath.Join(dir, "not-yet-started.sock")
+	gotSocket, gotPrefix, ok = ParseUnixSocketServer("unix://" + notOnDisk)
+	if !ok {
+		t.Fatalf("ParseUnixSocketServer returned ok=false for a socket not yet on disk")
+	}
+	if gotSocket != notOnDisk {
+		t.Errorf("socket path = %q, want %q", gotSocket, notOnDisk)
+	}
+	if gotPrefix != "" {
+		t.Errorf("url prefix = %q, want empty", gotPrefix)
+	}
+}
+
+func TestValidateServerAddress(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "app.sock")
+	if err := os.WriteFile(socketPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []string{
+		"example.com:443",
+		"http://example.com",
+		"https://example.com",
+		"unix://" + socketPath,
+	}
+	for _, server := range valid {
+		if err := ValidateServerAddress(server); err != nil {
+			t.Errorf("ValidateServerAddress(%q) = %v, want nil", server, err)
+		}
+	}
+
+	invalid := []string{
+		"example.com",
+		"unix://",
+	}
+	for _, server := range invalid {
+		if err := ValidateServerAddress(server); err == nil {
+			t.Errorf("ValidateServerAddress(%q) = nil, want an error", server)
+		}
+	}
+}